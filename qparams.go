@@ -4,9 +4,14 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
+	"unicode"
 )
 
 type (
@@ -80,9 +85,14 @@ func (s *Slice) ToFloatAtIndex(i int) (float64, error) {
 
 // TODO - Add tofloat etc... and do the same for Map
 
-// ErrWrongDestType is used when the provided dest is not struct pointer
+// ErrWrongDestType is returned by Parse when dest is not a pointer to a
+// struct, and by Marshal when src is neither a struct nor a pointer to one.
 var ErrWrongDestType = errors.New("Dest must be a struct pointer")
 
+// DestTypeError is a deprecated alias for ErrWrongDestType, kept for
+// backward compatibility.
+var DestTypeError = ErrWrongDestType
+
 var emptyVal interface{}
 var emptyInterface = reflect.ValueOf(&emptyVal).Type().Elem().Kind()
 
@@ -99,60 +109,409 @@ func (e TypeConvErrors) Error() string {
 	return str
 }
 
+// Converter decodes a raw query string into a reflect.Value of the type
+// it was registered for. It is used to fill Slice/Map elements and plain
+// struct fields whose type isn't handled by the built-in converters.
+type Converter func(value string) (reflect.Value, error)
+
+// converters holds the built-in scalar converters, keyed by the exact
+// type they produce. RegisterConverter adds to this table.
+var converters = map[reflect.Type]Converter{
+	reflect.TypeOf(""):          convertString,
+	reflect.TypeOf(int(0)):      convertInt(reflect.TypeOf(int(0)), 0),
+	reflect.TypeOf(int8(0)):     convertInt(reflect.TypeOf(int8(0)), 8),
+	reflect.TypeOf(int16(0)):    convertInt(reflect.TypeOf(int16(0)), 16),
+	reflect.TypeOf(int32(0)):    convertInt(reflect.TypeOf(int32(0)), 32),
+	reflect.TypeOf(int64(0)):    convertInt(reflect.TypeOf(int64(0)), 64),
+	reflect.TypeOf(uint(0)):     convertUint(reflect.TypeOf(uint(0)), 0),
+	reflect.TypeOf(uint8(0)):    convertUint(reflect.TypeOf(uint8(0)), 8),
+	reflect.TypeOf(uint16(0)):   convertUint(reflect.TypeOf(uint16(0)), 16),
+	reflect.TypeOf(uint32(0)):   convertUint(reflect.TypeOf(uint32(0)), 32),
+	reflect.TypeOf(uint64(0)):   convertUint(reflect.TypeOf(uint64(0)), 64),
+	reflect.TypeOf(float32(0)):  convertFloat(reflect.TypeOf(float32(0)), 32),
+	reflect.TypeOf(float64(0)):  convertFloat(reflect.TypeOf(float64(0)), 64),
+	reflect.TypeOf(false):       convertBool,
+	reflect.TypeOf(time.Time{}): convertTime,
+}
+
+// RegisterConverter registers a Converter for t, so Parse can decode
+// query values directly into struct fields (or Slice/Map elements) of
+// that type - UUIDs, enums, time.Duration, and so on.
+func RegisterConverter(t reflect.Type, conv Converter) {
+	converters[t] = conv
+}
+
+func converterFor(t reflect.Type) (Converter, bool) {
+	conv, ok := converters[t]
+	return conv, ok
+}
+
+func convertString(value string) (reflect.Value, error) {
+	return reflect.ValueOf(value), nil
+}
+
+func convertInt(t reflect.Type, bitSize int) Converter {
+	return func(value string) (reflect.Value, error) {
+		i, err := strconv.ParseInt(value, 10, bitSize)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("%q is not a valid integer", value)
+		}
+
+		return reflect.ValueOf(i).Convert(t), nil
+	}
+}
+
+func convertUint(t reflect.Type, bitSize int) Converter {
+	return func(value string) (reflect.Value, error) {
+		u, err := strconv.ParseUint(value, 10, bitSize)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("%q is not a valid unsigned integer", value)
+		}
+
+		return reflect.ValueOf(u).Convert(t), nil
+	}
+}
+
+func convertFloat(t reflect.Type, bitSize int) Converter {
+	return func(value string) (reflect.Value, error) {
+		f, err := strconv.ParseFloat(value, bitSize)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("%q is not a valid float", value)
+		}
+
+		return reflect.ValueOf(f).Convert(t), nil
+	}
+}
+
+func convertBool(value string) (reflect.Value, error) {
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("%q is not a valid bool", value)
+	}
+
+	return reflect.ValueOf(b), nil
+}
+
+// convertTime parses value as RFC3339, the format time.Time's own
+// MarshalText/UnmarshalText (and so encoding/json) uses.
+func convertTime(value string) (reflect.Value, error) {
+	tm, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("%q is not a valid RFC3339 time", value)
+	}
+
+	return reflect.ValueOf(tm), nil
+}
+
 var separator = ","
 var mapOpsTagSeparator = ","
 
+// NameMapper translates a struct field's name into the query key Parse
+// and Marshal look for, when the field has no `name:` tag override.
+type NameMapper func(string) string
+
+// nameMapper is the active NameMapper, consulted by Parse/Marshal
+// whenever a field has no explicit `name:` tag. Defaults to LowerCase,
+// qparams' original field-to-key behavior.
+var nameMapper NameMapper = LowerCase
+
+// SetNameMapper replaces the package-wide default NameMapper used by
+// Parse and Marshal. Pass LowerCase, SnakeCase, KebabCase, or a custom
+// func(string) string - e.g. so a UserID field matches a ?user_id=...
+// query key without tagging every field `qparams:"name:user_id"`.
+func SetNameMapper(m NameMapper) {
+	if m == nil {
+		return
+	}
+
+	nameMapper = m
+}
+
+// LowerCase is qparams' original NameMapper: it lowercases the field
+// name verbatim, e.g. "UserID" -> "userid".
+func LowerCase(name string) string {
+	return strings.ToLower(name)
+}
+
+// SnakeCase maps a CamelCase field name to snake_case, e.g.
+// "UserID" -> "user_id".
+func SnakeCase(name string) string {
+	return delimitCase(name, "_")
+}
+
+// KebabCase maps a CamelCase field name to kebab-case, e.g.
+// "UserID" -> "user-id".
+func KebabCase(name string) string {
+	return delimitCase(name, "-")
+}
+
+// delimitCase lowercases name, inserting delim at each new-word
+// boundary: before a capital preceded by a lowercase letter ("Id" in
+// "UserId"), and before the last capital of a run that's followed by a
+// lowercase letter ("N" in "IDName", but not the "D" in a trailing
+// "...ID").
+func delimitCase(name, delim string) string {
+	var b strings.Builder
+
+	runes := []rune(name)
+
+	for i, r := range runes {
+		if !unicode.IsUpper(r) {
+			b.WriteRune(r)
+			continue
+		}
+
+		prevLower := i > 0 && !unicode.IsUpper(runes[i-1])
+		nextLower := i+1 < len(runes) && !unicode.IsUpper(runes[i+1])
+
+		if i > 0 && (prevLower || nextLower) {
+			b.WriteString(delim)
+		}
+
+		b.WriteRune(unicode.ToLower(r))
+	}
+
+	return b.String()
+}
+
+// addErrs flattens err into errs, expanding a TypeConvErrors into its
+// individual messages rather than nesting it as a single entry.
+func addErrs(errs *TypeConvErrors, err error) {
+	if err == nil {
+		return
+	}
+
+	if te, ok := err.(TypeConvErrors); ok {
+		*errs = append(*errs, te...)
+		return
+	}
+
+	*errs = append(*errs, err.Error())
+}
+
 // Parse will try to parse query params from http.Request to
 // provided struct, and will return error on filure
 func Parse(dest interface{}, r *http.Request) error {
+	return ParseWith(dest, r)
+}
+
+// ParseOption configures a single ParseWith call.
+type ParseOption func(*parseConfig)
+
+type parseConfig struct {
+	nameMapper NameMapper
+}
+
+// WithNameMapper overrides the NameMapper for a single ParseWith call,
+// without changing the package-wide default set by SetNameMapper.
+func WithNameMapper(m NameMapper) ParseOption {
+	return func(c *parseConfig) {
+		if m != nil {
+			c.nameMapper = m
+		}
+	}
+}
+
+// ParseWith is Parse with per-call options, e.g. WithNameMapper to use a
+// different field-to-query-key mapping than the package-wide default.
+func ParseWith(dest interface{}, r *http.Request, opts ...ParseOption) error {
+	cfg := parseConfig{nameMapper: nameMapper}
+
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
 	var errs = TypeConvErrors{}
 
 	t := reflect.TypeOf(dest)
 	v := reflect.ValueOf(dest)
-	queryValues := r.URL.Query()
 
 	if t.Kind() != reflect.Ptr ||
 		t.Elem().Kind() != reflect.Struct {
 		return ErrWrongDestType
 	}
 
-	// TODO: - Cache struct meta data
+	parseStruct(t.Elem(), v.Elem(), r.URL.Query(), &errs, cfg.nameMapper)
 
-	for i := 0; i < v.Elem().NumField(); i++ {
-		fieldT := t.Elem().Field(i)
-		fieldV := v.Elem().Field(i)
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// fieldMeta holds a struct field's qparams tag options, already parsed
+// and resolved, so parseStruct doesn't re-derive them on every request.
+type fieldMeta struct {
+	field      reflect.StructField
+	nameTag    string
+	sep        string
+	operators  []string
+	defaultVal string
+	required   bool
+	enum       []string
+	minTag     string
+	maxTag     string
+}
+
+// structMeta is the precompiled descriptor for a struct type, cached in
+// metaCache keyed by reflect.Type.
+type structMeta struct {
+	fields []fieldMeta
+}
+
+// metaCache holds one *structMeta per struct type parseStruct has seen,
+// populated lazily by precompileType (or eagerly by Precompile).
+var metaCache sync.Map
+
+// precompileType returns t's cached structMeta, building and storing it
+// on first use.
+func precompileType(t reflect.Type) *structMeta {
+	if cached, ok := metaCache.Load(t); ok {
+		return cached.(*structMeta)
+	}
+
+	meta := buildStructMeta(t)
+
+	actual, _ := metaCache.LoadOrStore(t, meta)
+
+	return actual.(*structMeta)
+}
+
+func buildStructMeta(t reflect.Type) *structMeta {
+	fields := make([]fieldMeta, t.NumField())
+
+	for i := range fields {
+		fields[i] = buildFieldMeta(t.Field(i))
+	}
+
+	return &structMeta{fields: fields}
+}
+
+// buildFieldMeta resolves fieldT's qparams tag options once, including
+// pre-sorting its operators longest-first so walk doesn't have to. The
+// field's effective query key isn't resolved here - it depends on the
+// active NameMapper, which can vary per ParseWith call - so parseStruct
+// falls back to the mapper itself whenever nameTag is empty.
+func buildFieldMeta(fieldT reflect.StructField) fieldMeta {
+	var operators []string
+
+	if ops := getTag("ops", fieldT); ops != "" {
+		operators = strings.Split(ops, mapOpsTagSeparator)
+		sort.SliceStable(operators, func(i, j int) bool {
+			return len(operators[i]) > len(operators[j])
+		})
+	}
+
+	var enum []string
+
+	if e := getTag("enum", fieldT); e != "" {
+		enum = strings.Split(e, "|")
+	}
+
+	return fieldMeta{
+		field:      fieldT,
+		nameTag:    getTag("name", fieldT),
+		sep:        getSeparator(fieldT),
+		operators:  operators,
+		defaultVal: getTag("default", fieldT),
+		required:   hasTag("required", fieldT),
+		enum:       enum,
+		minTag:     getTag("min", fieldT),
+		maxTag:     getTag("max", fieldT),
+	}
+}
+
+// Precompile warms the struct metadata cache for sampleStruct's type (or
+// the struct it points to), so the first real Parse call doesn't pay the
+// cost of deriving its qparams tags. It is a no-op if sampleStruct isn't
+// ultimately a struct.
+func Precompile(sampleStruct interface{}) {
+	t := reflect.TypeOf(sampleStruct)
+
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == nil || t.Kind() != reflect.Struct {
+		return
+	}
+
+	precompileType(t)
+}
+
+// parseStruct fills v (of struct type t) from queryValues. It recurses
+// into nested struct/pointer-to-struct fields using bracketed keys, e.g.
+// "user[name]=Bob" addresses a User field named Name; anonymous embedded
+// structs are recursed into without a bracket prefix, promoting their
+// fields into the parent's query namespace (as go-micro's reflect
+// helpers do).
+func parseStruct(t reflect.Type, v reflect.Value, queryValues url.Values, errs *TypeConvErrors, mapper NameMapper) {
+	meta := precompileType(t)
+
+	groups := groupBracketed(queryValues)
+
+	for i := range meta.fields {
+		fm := &meta.fields[i]
+		fieldT := fm.field
+		fieldV := v.Field(i)
+
+		if !fieldV.CanSet() {
+			continue
+		}
 
 		vv := fieldV
-		// fmt.Printf("vv Kind(): %#v: isSlice: %#v: isMap: %#v\n", vv.Kind(), reflect.Slice == vv.Kind(), reflect.Map == vv.Kind())
-		// fmt.Printf("vv Type(): %#v\n", vv.Type().Name())
 
-		// if reflect.Slice == vv.Kind() {
-		// 	infoValSlice(vv)
-		// }
-		// if reflect.Map == vv.Kind() {
-		// 	infoValMap(vv)
-		// }
+		if fieldT.Anonymous && vv.Kind() == reflect.Struct && vv.Type() != reflect.TypeOf(time.Time{}) {
+			parseStruct(fieldT.Type, fieldV, queryValues, errs, mapper)
+			continue
+		}
 
-		fieldName := strings.ToLower(fieldT.Name)
-		// fmt.Println("fieldT.Name:", fieldName)
+		fieldName := fm.nameTag
+		if fieldName == "" {
+			fieldName = mapper(fieldT.Name)
+		}
 
-		if tagFieldName := getTag("name", fieldT); tagFieldName != "" {
-			fieldName = tagFieldName
+		if vv.Kind() == reflect.Ptr && vv.Type().Elem().Kind() == reflect.Struct {
+			sub, ok := groups[fieldName]
+			if !ok {
+				continue
+			}
+
+			if vv.IsNil() {
+				vv.Set(reflect.New(vv.Type().Elem()))
+			}
+
+			parseStruct(vv.Type().Elem(), vv.Elem(), sub, errs, mapper)
+
+			continue
 		}
 
-		sep := getSeparator(fieldT)
+		if vv.Kind() == reflect.Struct && vv.Type() != reflect.TypeOf(time.Time{}) {
+			sub, ok := groups[fieldName]
+			if !ok {
+				continue
+			}
 
+			parseStruct(fieldT.Type, fieldV, sub, errs, mapper)
+
+			continue
+		}
+
+		sep := fm.sep
+
+		// Repeated keys (e.g. "?tag=a&tag=b") arrive in val as multiple
+		// elements; for Slice/Map fields they're rejoined with the
+		// field's own separator so every repetition - not just the
+		// first - reaches parseSlice/parseMap below.
 		for key, val := range queryValues {
 			key = strings.ToLower(key)
-			// fmt.Println("key:", key)
 			if fieldName != key {
 				continue
 			}
 			r := []string{}
-			// fmt.Printf("fieldT.Type.Name():__%#v\n", fieldT.Type.Name())
 			switch vv.Kind() {
 			case reflect.Slice, reflect.Map:
-				// fmt.Printf("trim []string: %#v\n", val)
 				for _, n := range val {
 					r = append(r, strings.Trim(n, ","+sep))
 				}
@@ -166,20 +525,17 @@ func Parse(dest interface{}, r *http.Request) error {
 				if vv.IsNil() {
 					vv.Set(reflect.MakeMap(t))
 				}
-				if t.Key().Name() != "string" || t.Elem().Kind() != reflect.String {
+				if t.Key().Kind() != reflect.String {
 					continue
 				}
-				queryValues[key] = []string{strings.Join(r, ",")}
+				queryValues[key] = []string{strings.Join(r, sep)}
 			case reflect.Slice:
-				t := vv.Type()
-				// allocate a new map, if v is nil. see: m2, m3, m4.
-				if vv.IsNil() {
-					vv.Set(reflect.MakeSlice(t, 0, 0))
-				}
-				if t.Elem().String() != "string" {
-					continue
-				}
-				// fmt.Printf("join []string: %#v\n", r)
+				// Leave a nil slice nil here - parseSlice and
+				// parseIndexedSlice allocate it themselves once there's
+				// an actual value to fill it with. Pre-allocating
+				// unconditionally would turn an absent/empty param into
+				// a non-nil empty slice instead of leaving the field at
+				// its zero value.
 				queryValues[key] = []string{strings.Join(r, sep)}
 			default:
 				queryValues[key] = r
@@ -189,54 +545,350 @@ func Parse(dest interface{}, r *http.Request) error {
 		queryValue := queryValues.Get(fieldName)
 
 		if queryValue == "" {
-			// TODO - Set default value here
-			// fmt.Println("-------------------------")
-			continue
+			queryValue = fm.defaultVal
 		}
 
-		switch vv.Kind() {
-		case reflect.Map:
-			parseMap(fieldT, fieldV, queryValue)
-		case reflect.Slice:
-			// fmt.Printf("fill []string: %#v\n", queryValue)
-			parseSlice(fieldT, fieldV, queryValue)
-		}
+		if queryValue == "" {
+			handledByIndex := false
 
-		switch fieldV.Kind() {
-		case reflect.Int, reflect.Int32:
-			err := parseInt(fieldT, fieldV, queryValue)
-			if err != nil {
-				errs = append(errs, err.Error())
-			}
-		case reflect.Int64:
-			err := parseInt64(fieldT, fieldV, queryValue)
-			if err != nil {
-				errs = append(errs, err.Error())
+			switch vv.Kind() {
+			case reflect.Slice:
+				if idx, ok := groups[fieldName]; ok {
+					addErrs(errs, parseIndexedSlice(fm, fieldV, idx))
+					handledByIndex = true
+				}
+			case reflect.Map:
+				if idx, ok := groups[fieldName]; ok {
+					addErrs(errs, parseIndexedMap(fm, fieldV, idx))
+					handledByIndex = true
+				}
 			}
-		case reflect.Float64:
-			err := parseFloat(fieldT, fieldV, queryValue, 64)
-			if err != nil {
-				errs = append(errs, err.Error())
+
+			if !handledByIndex {
+				if fm.required {
+					*errs = append(*errs, fmt.Sprintf("Field %s is required", fieldT.Name))
+				}
+
+				continue
 			}
-		case reflect.Float32:
-			err := parseFloat(fieldT, fieldV, queryValue, 32)
-			if err != nil {
-				errs = append(errs, err.Error())
+		} else {
+			switch vv.Kind() {
+			case reflect.Map:
+				addErrs(errs, parseMap(fm, fieldV, queryValue))
+			case reflect.Slice:
+				addErrs(errs, parseSlice(fm, fieldV, queryValue))
+			default:
+				addErrs(errs, parseScalar(fm, fieldV, queryValue))
 			}
-		case reflect.String:
-			parseString(fieldT, fieldV, queryValue)
 		}
 
-		// fmt.Println("-------------------------")
+		addErrs(errs, validateField(fm, fieldV))
+	}
+}
+
+// bracketKey splits a key of the form "name[rest]..." into its leading
+// name and the remaining key, which may itself carry further brackets,
+// e.g. "user[address][city]" -> ("user", "address[city]"). Keys without
+// a bracket are returned unchanged with an empty rest.
+func bracketKey(key string) (name, rest string) {
+	open := strings.IndexByte(key, '[')
+	if open == -1 {
+		return key, ""
+	}
+
+	closeIdx := strings.IndexByte(key[open:], ']')
+	if closeIdx == -1 {
+		return key, ""
+	}
+
+	closeIdx += open
+
+	return key[:open], key[open+1:closeIdx] + key[closeIdx+1:]
+}
+
+// groupBracketed buckets the bracketed entries of queryValues (such as
+// "user[name]=Bob" or "filter[0]=a") by their leading name, turning the
+// remainder of each key into a plain key a nested parseStruct call (or
+// parseIndexedSlice) can match against.
+func groupBracketed(queryValues url.Values) map[string]url.Values {
+	var groups map[string]url.Values
+
+	for key, vals := range queryValues {
+		if !strings.ContainsRune(key, '[') {
+			continue
+		}
+
+		name, rest := bracketKey(key)
+		if rest == "" {
+			continue
+		}
+
+		name = strings.ToLower(name)
+
+		if groups == nil {
+			groups = map[string]url.Values{}
+		}
+
+		if groups[name] == nil {
+			groups[name] = url.Values{}
+		}
+
+		groups[name][rest] = append(groups[name][rest], vals...)
+	}
+
+	return groups
+}
+
+// parseIndexedSlice fills a Slice-kinded field from bracket-indexed keys
+// such as "filter[0]=a&filter[1]=b", ordering elements by their numeric
+// index. Non-numeric keys are ignored, and gaps between indices are
+// not kept ("filter[0]=a&filter[5]=b" yields two elements).
+func parseIndexedSlice(fm *fieldMeta, fieldV reflect.Value, idx url.Values) error {
+	indices := make([]int, 0, len(idx))
+
+	for k := range idx {
+		n, err := strconv.Atoi(k)
+		if err != nil {
+			continue
+		}
+
+		indices = append(indices, n)
+	}
+
+	sort.Ints(indices)
+
+	t := fieldV.Type()
+	newSlice := reflect.MakeSlice(t, 0, len(indices))
+
+	var errs TypeConvErrors
+
+	for _, n := range indices {
+		val := idx.Get(strconv.Itoa(n))
+
+		if t.Elem().Kind() == reflect.String {
+			newSlice = reflect.Append(newSlice, reflect.ValueOf(strings.ToLower(val)).Convert(t.Elem()))
+			continue
+		}
+
+		conv, ok := converterFor(t.Elem())
+		if !ok {
+			errs = append(errs, fmt.Sprintf("Field %s: no converter registered for slice element type %s", fm.field.Name, t.Elem()))
+			continue
+		}
+
+		elem, err := conv(val)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("Field %s: %s", fm.field.Name, err))
+			continue
+		}
+
+		newSlice = reflect.Append(newSlice, elem)
+	}
+
+	fieldV.Set(newSlice)
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// parseIndexedMap fills a Map-kinded field from bracket-indexed keys
+// such as "m[a]=1&m[b]=2", one map entry per bracket key - unlike
+// parseMap's "key<op>value,..." shorthand, each key/value pair already
+// has its own query parameter, so no operator or separator is involved.
+// qparams.Map (and any other map[string]string) is filled directly;
+// any other map[string]T looks up a Converter for T to decode each value.
+func parseIndexedMap(fm *fieldMeta, fieldV reflect.Value, idx url.Values) error {
+	t := fieldV.Type()
+	if fieldV.IsNil() {
+		fieldV.Set(reflect.MakeMap(t))
+	}
+
+	if t.Key().Kind() != reflect.String {
+		return nil
+	}
+
+	if t.Elem().Kind() == reflect.String {
+		for k := range idx {
+			fieldV.SetMapIndex(reflect.ValueOf(strings.ToLower(k)), reflect.ValueOf(idx.Get(k)).Convert(t.Elem()))
+		}
+
+		return nil
+	}
+
+	conv, ok := converterFor(t.Elem())
+	if !ok {
+		return fmt.Errorf("Field %s: no converter registered for map value type %s", fm.field.Name, t.Elem())
+	}
+
+	var errs TypeConvErrors
+
+	for k := range idx {
+		val, err := conv(idx.Get(k))
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("Field %s: %s", fm.field.Name, err))
+			continue
+		}
+
+		fieldV.SetMapIndex(reflect.ValueOf(strings.ToLower(k)), val)
 	}
 
-	if errs != nil && len(errs) > 0 {
+	if len(errs) > 0 {
 		return errs
 	}
 
 	return nil
 }
 
+// Marshal encodes a struct (or pointer to struct) into url.Values,
+// inverting Parse. Nested struct/pointer-to-struct fields are encoded
+// using the same bracketed key scheme Parse reads back ("user[name]=Bob");
+// anonymous embedded structs are promoted into the parent's namespace.
+// Slice and Map fields are serialized using the same qparams struct tag
+// options (name, sep) used to decode them - a Map's operator is taken from
+// each entry's "field op" key rather than the ops tag - and time.Time values are
+// formatted as RFC3339 to match convertTime. A field tagged with
+// `qparams:"omitempty"` is skipped when it holds its zero value.
+func Marshal(src interface{}) (url.Values, error) {
+	t := reflect.TypeOf(src)
+	v := reflect.ValueOf(src)
+
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		v = v.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return nil, ErrWrongDestType
+	}
+
+	values := url.Values{}
+
+	marshalStruct(t, v, values, "")
+
+	return values, nil
+}
+
+// marshalStruct encodes t/v's fields into values, recursing into nested
+// struct/pointer-to-struct fields under a bracketed key built from
+// prefix ("" at the top level). Anonymous embedded structs recurse
+// without adding a bracket level, mirroring parseStruct.
+func marshalStruct(t reflect.Type, v reflect.Value, values url.Values, prefix string) {
+	for i := 0; i < v.NumField(); i++ {
+		fieldT := t.Field(i)
+		fieldV := v.Field(i)
+
+		if hasTag("omitempty", fieldT) && fieldV.IsZero() {
+			continue
+		}
+
+		if fieldT.Anonymous && fieldV.Kind() == reflect.Struct && fieldV.Type() != reflect.TypeOf(time.Time{}) {
+			marshalStruct(fieldT.Type, fieldV, values, prefix)
+			continue
+		}
+
+		fieldName := nameMapper(fieldT.Name)
+
+		if tagFieldName := getTag("name", fieldT); tagFieldName != "" {
+			fieldName = tagFieldName
+		}
+
+		key := fieldName
+		if prefix != "" {
+			key = prefix + "[" + fieldName + "]"
+		}
+
+		if fieldV.Kind() == reflect.Ptr && fieldV.Type().Elem().Kind() == reflect.Struct {
+			if fieldV.IsNil() {
+				continue
+			}
+
+			marshalStruct(fieldV.Type().Elem(), fieldV.Elem(), values, key)
+
+			continue
+		}
+
+		if fieldV.Kind() == reflect.Struct && fieldV.Type() != reflect.TypeOf(time.Time{}) {
+			marshalStruct(fieldT.Type, fieldV, values, key)
+			continue
+		}
+
+		sep := getSeparator(fieldT)
+
+		switch fieldV.Kind() {
+		case reflect.Map:
+			if fieldV.Len() == 0 {
+				continue
+			}
+			values.Set(key, marshalMap(fieldV, sep))
+		case reflect.Slice:
+			if fieldV.Len() == 0 {
+				continue
+			}
+			values.Set(key, marshalSlice(fieldV, sep))
+		default:
+			values.Set(key, marshalScalar(fieldV))
+		}
+	}
+}
+
+// marshalScalar formats a non-Slice/Map field for the query string,
+// using RFC3339 for time.Time so convertTime can read it back.
+func marshalScalar(fieldV reflect.Value) string {
+	if tm, ok := fieldV.Interface().(time.Time); ok {
+		return tm.Format(time.RFC3339)
+	}
+
+	return fmt.Sprintf("%v", fieldV.Interface())
+}
+
+// MarshalString is a convenience wrapper around Marshal that returns the
+// already-encoded query string instead of url.Values.
+func MarshalString(src interface{}) (string, error) {
+	values, err := Marshal(src)
+	if err != nil {
+		return "", err
+	}
+
+	return values.Encode(), nil
+}
+
+// marshalMap turns a Map field back into its "field<op>value[,...]"
+// representation, the inverse of walk. Parts are sorted so the encoding
+// doesn't vary across calls with Go's randomized map iteration order.
+func marshalMap(fieldV reflect.Value, sep string) string {
+	parts := make([]string, 0, fieldV.Len())
+
+	for _, key := range fieldV.MapKeys() {
+		k := strings.Replace(key.String(), " ", "", 1)
+		mv := fieldV.MapIndex(key)
+		val := mv.String()
+		if mv.Kind() != reflect.String {
+			val = marshalScalar(mv)
+		}
+
+		parts = append(parts, k+val)
+	}
+
+	sort.Strings(parts)
+
+	return strings.Join(parts, sep)
+}
+
+// marshalSlice joins a Slice field's members with sep, the inverse of
+// parseSlice.
+func marshalSlice(fieldV reflect.Value, sep string) string {
+	parts := make([]string, fieldV.Len())
+
+	for i := 0; i < fieldV.Len(); i++ {
+		parts[i] = marshalScalar(fieldV.Index(i))
+	}
+
+	return strings.Join(parts, sep)
+}
+
 func getTag(tag string, sField reflect.StructField) string {
 	tags := sField.Tag.Get("qparams")
 
@@ -259,6 +911,24 @@ func getTag(tag string, sField reflect.StructField) string {
 	return ""
 }
 
+// hasTag reports whether the qparams tag carries a bare option (one
+// without a "name:value" form), e.g. `qparams:"omitempty"`.
+func hasTag(tag string, sField reflect.StructField) bool {
+	tags := sField.Tag.Get("qparams")
+
+	if tags == "" {
+		return false
+	}
+
+	for _, t := range strings.Split(tags, " ") {
+		if t == tag {
+			return true
+		}
+	}
+
+	return false
+}
+
 func getSeparator(sField reflect.StructField) string {
 	sep := separator
 
@@ -269,80 +939,283 @@ func getSeparator(sField reflect.StructField) string {
 	return sep
 }
 
-func getOperators(sField reflect.StructField) []string {
-	operators := []string{}
+// validateField runs the enum and min/max constraints resolved onto fm
+// against the already-parsed fieldV, accumulating every violation
+// instead of stopping at the first.
+func validateField(fm *fieldMeta, fieldV reflect.Value) error {
+	var errs TypeConvErrors
 
-	if ops := getTag("ops", sField); ops != "" {
-		operators = strings.Split(ops, mapOpsTagSeparator)
+	if fm.enum != nil {
+		addErrs(&errs, validateEnum(fm, fieldV, fm.enum))
 	}
 
-	return operators
+	addErrs(&errs, validateMinMax(fm, fieldV))
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
 }
 
-func parseMap(sField reflect.StructField, fieldV reflect.Value, queryValue string) {
-	sep := getSeparator(sField)
+// validateEnum checks that fieldV (a string, or each element of a
+// string slice) is one of enum.
+func validateEnum(fm *fieldMeta, fieldV reflect.Value, enum []string) error {
+	allowed := make(map[string]bool, len(enum))
+	for _, e := range enum {
+		allowed[e] = true
+	}
 
-	operators := getOperators(sField)
-	// TODO: - Throw error if no operators provided
+	var values []string
+
+	switch fieldV.Kind() {
+	case reflect.String:
+		values = []string{fieldV.String()}
+	case reflect.Slice:
+		values = make([]string, fieldV.Len())
+		for i := range values {
+			values[i] = fmt.Sprintf("%v", fieldV.Index(i).Interface())
+		}
+	default:
+		return nil
+	}
+
+	var errs TypeConvErrors
 
-	// TODO - handle error
-	parsedMap := walk(queryValue, sep, operators)
+	for _, v := range values {
+		if !allowed[v] {
+			errs = append(errs, fmt.Sprintf("Field %s: %q is not one of %s", fm.field.Name, v, strings.Join(enum, "|")))
+		}
+	}
 
-	fieldV.Set(reflect.ValueOf(parsedMap))
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
 }
 
-func parseSlice(sField reflect.StructField, fieldV reflect.Value, queryValue string) {
-	sep := getSeparator(sField)
+// validateMinMax checks fm's min/max tag options against a numeric
+// field's value, or a slice field's length.
+func validateMinMax(fm *fieldMeta, fieldV reflect.Value) error {
+	minTag := fm.minTag
+	maxTag := fm.maxTag
 
-	slice := strings.Split(queryValue, sep)
+	if minTag == "" && maxTag == "" {
+		return nil
+	}
 
-	newSlice := []string{}
+	var errs TypeConvErrors
 
-	for _, val := range slice {
-		v := strings.ToLower(val)
-		if v != "" {
-			newSlice = append(newSlice, v)
+	switch {
+	case isNumericKind(fieldV.Kind()):
+		n := numericValue(fieldV)
+
+		if minTag != "" {
+			if min, err := strconv.ParseFloat(minTag, 64); err == nil && n < min {
+				errs = append(errs, fmt.Sprintf("Field %s: %v is less than the minimum %v", fm.field.Name, n, min))
+			}
+		}
+
+		if maxTag != "" {
+			if max, err := strconv.ParseFloat(maxTag, 64); err == nil && n > max {
+				errs = append(errs, fmt.Sprintf("Field %s: %v is greater than the maximum %v", fm.field.Name, n, max))
+			}
+		}
+	case fieldV.Kind() == reflect.Slice:
+		length := fieldV.Len()
+
+		if minTag != "" {
+			if min, err := strconv.Atoi(minTag); err == nil && length < min {
+				errs = append(errs, fmt.Sprintf("Field %s: has %d elements, fewer than the minimum %d", fm.field.Name, length, min))
+			}
+		}
+
+		if maxTag != "" {
+			if max, err := strconv.Atoi(maxTag); err == nil && length > max {
+				errs = append(errs, fmt.Sprintf("Field %s: has %d elements, more than the maximum %d", fm.field.Name, length, max))
+			}
 		}
 	}
 
-	fieldV.Set(reflect.ValueOf(newSlice))
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
 }
 
-func parseInt(sField reflect.StructField, fieldV reflect.Value, queryValue string) error {
-	i, err := strconv.Atoi(queryValue)
-	if err != nil {
-		return fmt.Errorf("Field %s does not contain a valid integer (%s)", sField.Name, queryValue)
+func isNumericKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
 	}
 
-	fieldV.Set(reflect.ValueOf(i))
+	return false
+}
 
-	return nil
+func numericValue(v reflect.Value) float64 {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	}
+
+	return 0
 }
 
-func parseInt64(sField reflect.StructField, fieldV reflect.Value, queryValue string) error {
-	i64, err := strconv.ParseInt(queryValue, 10, 0)
-	if err != nil {
-		return fmt.Errorf("Field %s does not contain a valid integer (%s)", sField.Name, queryValue)
+// walk splits queryValue on sep and, for each part, finds the first
+// operator in operators that appears in it, turning "field<op>value"
+// into a Map entry keyed by "field op". Callers must pass operators
+// already sorted longest-first (buildFieldMeta does this), so e.g. "=="
+// is tried before "=".
+func walk(queryValue, sep string, operators []string) Map {
+	m := Map{}
+
+	for _, part := range strings.Split(queryValue, sep) {
+		if part == "" {
+			continue
+		}
+
+		for _, op := range operators {
+			idx := strings.Index(part, op)
+			if idx == -1 {
+				continue
+			}
+
+			key := strings.ToLower(part[:idx])
+			val := part[idx+len(op):]
+
+			m[key+" "+op] = val
+
+			break
+		}
 	}
 
-	fieldV.Set(reflect.ValueOf(i64))
+	return m
+}
+
+// parseMap fills a Map-kinded field from queryValue. qparams.Map (and any
+// other map[string]string) is filled directly from walk's output; any
+// other map[string]T looks up a Converter for T to decode each value.
+func parseMap(fm *fieldMeta, fieldV reflect.Value, queryValue string) error {
+	// TODO: - Throw error if no operators provided
+
+	parsedMap := walk(queryValue, fm.sep, fm.operators)
+
+	t := fieldV.Type()
+	if fieldV.IsNil() {
+		fieldV.Set(reflect.MakeMap(t))
+	}
+
+	if t.Elem().Kind() == reflect.String {
+		for k, v := range parsedMap {
+			fieldV.SetMapIndex(reflect.ValueOf(k), reflect.ValueOf(v).Convert(t.Elem()))
+		}
+
+		return nil
+	}
+
+	conv, ok := converterFor(t.Elem())
+	if !ok {
+		return fmt.Errorf("Field %s: no converter registered for map value type %s", fm.field.Name, t.Elem())
+	}
+
+	var errs TypeConvErrors
+
+	for k, v := range parsedMap {
+		val, err := conv(v)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("Field %s: %s", fm.field.Name, err))
+			continue
+		}
+
+		fieldV.SetMapIndex(reflect.ValueOf(k), val)
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
 
 	return nil
 }
 
-func parseFloat(sField reflect.StructField, fieldV reflect.Value, queryValue string, bitSize int) error {
-	f, err := strconv.ParseFloat(queryValue, bitSize)
-	if err != nil {
-		return fmt.Errorf("Field %s does not contain a valid float (%s)", sField.Name, queryValue)
+// parseSlice fills a Slice-kinded field from queryValue. qparams.Slice
+// (and any other []string) keeps the original lowercasing behavior; any
+// other []T looks up a Converter for T to decode each element.
+func parseSlice(fm *fieldMeta, fieldV reflect.Value, queryValue string) error {
+	parts := strings.Split(queryValue, fm.sep)
+
+	t := fieldV.Type()
+	newSlice := reflect.MakeSlice(t, 0, len(parts))
+
+	if t.Elem().Kind() == reflect.String {
+		for _, val := range parts {
+			v := strings.ToLower(val)
+			if v == "" {
+				continue
+			}
+
+			newSlice = reflect.Append(newSlice, reflect.ValueOf(v).Convert(t.Elem()))
+		}
+
+		fieldV.Set(newSlice)
+
+		return nil
 	}
 
-	fieldV.Set(reflect.ValueOf(f))
+	conv, ok := converterFor(t.Elem())
+	if !ok {
+		fieldV.Set(newSlice)
+		return fmt.Errorf("Field %s: no converter registered for slice element type %s", fm.field.Name, t.Elem())
+	}
+
+	var errs TypeConvErrors
+
+	for _, val := range parts {
+		if val == "" {
+			continue
+		}
+
+		elem, err := conv(val)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("Field %s: %s", fm.field.Name, err))
+			continue
+		}
+
+		newSlice = reflect.Append(newSlice, elem)
+	}
+
+	fieldV.Set(newSlice)
+
+	if len(errs) > 0 {
+		return errs
+	}
 
 	return nil
 }
 
-func parseString(sField reflect.StructField, fieldV reflect.Value, queryValue string) {
-	fieldV.Set(reflect.ValueOf(queryValue))
+// parseScalar fills a non-Slice/Map field from queryValue using the
+// Converter registered for its type.
+func parseScalar(fm *fieldMeta, fieldV reflect.Value, queryValue string) error {
+	conv, ok := converterFor(fieldV.Type())
+	if !ok {
+		return nil
+	}
+
+	val, err := conv(queryValue)
+	if err != nil {
+		return fmt.Errorf("Field %s: %s", fm.field.Name, err)
+	}
+
+	fieldV.Set(val)
+
+	return nil
 }
 
 // func infoValMap(dst reflect.Value) {
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"net/http"
 	"reflect"
+	"strconv"
 	"testing"
+	"time"
 )
 
 const failEmoji = "\x1b[31m\u2717\x1b[0m"
@@ -251,3 +253,651 @@ func TestParseMapWithCustomSeparator(t *testing.T) {
 		compare(t, c, opts, err)
 	}
 }
+
+// MARK - Marshal tests
+
+func TestMarshalWrongDest(t *testing.T) {
+	_, err := Marshal(42)
+
+	if err == DestTypeError {
+		pass(t, "Test pass", DestTypeError, err)
+	} else {
+		failFatal(t, "Test pass", DestTypeError, err)
+	}
+}
+
+func TestMarshalScalar(t *testing.T) {
+	type testStruct struct {
+		Name string
+		Age  int
+	}
+
+	opts := testStruct{Name: "Bob", Age: 30}
+
+	values, err := Marshal(&opts)
+	if err != nil {
+		failFatal(t, "Unexpected error", nil, err)
+	}
+
+	want := "Bob"
+	got := values.Get("name")
+	if got != want {
+		failFatal(t, "Test failed", want, got)
+	}
+	pass(t, "Test passed", want, got)
+
+	want = "30"
+	got = values.Get("age")
+	if got != want {
+		failFatal(t, "Test failed", want, got)
+	}
+	pass(t, "Test passed", want, got)
+}
+
+func TestMarshalSlice(t *testing.T) {
+	type testStruct struct {
+		Embed Slice `qparams:"sep:|"`
+	}
+
+	opts := testStruct{Embed: Slice{"user", "order", "discount"}}
+
+	values, err := Marshal(&opts)
+	if err != nil {
+		failFatal(t, "Unexpected error", nil, err)
+	}
+
+	want := "user|order|discount"
+	got := values.Get("embed")
+	if got != want {
+		failFatal(t, "Test failed", want, got)
+	}
+	pass(t, "Test passed", want, got)
+}
+
+func TestMarshalMap(t *testing.T) {
+	type testStruct struct {
+		Filter Map `qparams:"ops:>,==,<=,<,!=,-like-"`
+	}
+
+	opts := testStruct{Filter: Map{"age >": "7"}}
+
+	values, err := Marshal(&opts)
+	if err != nil {
+		failFatal(t, "Unexpected error", nil, err)
+	}
+
+	want := "age>7"
+	got := values.Get("filter")
+	if got != want {
+		failFatal(t, "Test failed", want, got)
+	}
+	pass(t, "Test passed", want, got)
+}
+
+func TestMarshalMapMultiEntryDeterministic(t *testing.T) {
+	type testStruct struct {
+		Filter Map `qparams:"ops:>,==,<=,<,!=,-like-"`
+	}
+
+	opts := testStruct{Filter: Map{"age >": "7", "gender ==": "0", "balance <=": "1000"}}
+
+	want, err := MarshalString(&opts)
+	if err != nil {
+		failFatal(t, "Unexpected error", nil, err)
+	}
+
+	for i := 0; i < 10; i++ {
+		got, err := MarshalString(&opts)
+		if err != nil {
+			failFatal(t, "Unexpected error", nil, err)
+		}
+		if got != want {
+			failFatal(t, "Test failed: non-deterministic encoding", want, got)
+		}
+	}
+	pass(t, "Test passed", want, want)
+}
+
+func TestMarshalNestedStruct(t *testing.T) {
+	type user struct {
+		Name string
+		Age  int
+	}
+
+	type testStruct struct {
+		User user
+	}
+
+	opts := testStruct{User: user{Name: "Bob", Age: 30}}
+
+	values, err := Marshal(&opts)
+	if err != nil {
+		failFatal(t, "Unexpected error", nil, err)
+	}
+
+	want := "Bob"
+	got := values.Get("user[name]")
+	if got != want {
+		failFatal(t, "Test failed", want, got)
+	}
+	pass(t, "Test passed", want, got)
+
+	want = "30"
+	got = values.Get("user[age]")
+	if got != want {
+		failFatal(t, "Test failed", want, got)
+	}
+	pass(t, "Test passed", want, got)
+}
+
+func TestMarshalTime(t *testing.T) {
+	type testStruct struct {
+		CreatedAt time.Time
+	}
+
+	tm, err := time.Parse(time.RFC3339, "2021-05-01T10:00:00Z")
+	if err != nil {
+		failFatal(t, "Unexpected error", nil, err)
+	}
+
+	opts := testStruct{CreatedAt: tm}
+
+	values, err := Marshal(&opts)
+	if err != nil {
+		failFatal(t, "Unexpected error", nil, err)
+	}
+
+	want := "2021-05-01T10:00:00Z"
+	got := values.Get("createdat")
+	if got != want {
+		failFatal(t, "Test failed", want, got)
+	}
+	pass(t, "Test passed", want, got)
+}
+
+func TestMarshalOmitempty(t *testing.T) {
+	type testStruct struct {
+		Name string `qparams:"omitempty"`
+		Age  int    `qparams:"omitempty"`
+	}
+
+	opts := testStruct{}
+
+	values, err := Marshal(&opts)
+	if err != nil {
+		failFatal(t, "Unexpected error", nil, err)
+	}
+
+	if values.Get("name") != "" || values.Get("age") != "" {
+		failFatal(t, "Test failed", "", values.Encode())
+	}
+	pass(t, "Test passed", "", values.Encode())
+}
+
+func TestMarshalString(t *testing.T) {
+	type testStruct struct {
+		Name string
+	}
+
+	opts := testStruct{Name: "Bob"}
+
+	str, err := MarshalString(&opts)
+	if err != nil {
+		failFatal(t, "Unexpected error", nil, err)
+	}
+
+	want := "name=Bob"
+	if str != want {
+		failFatal(t, "Test failed", want, str)
+	}
+	pass(t, "Test passed", want, str)
+}
+
+// MARK - Generalized scalar/Slice/Map tests
+
+func TestParseIntSlice(t *testing.T) {
+	type testStruct struct {
+		IDs []int
+	}
+
+	opts := testStruct{}
+	r := newRequest("foobar.com?ids=1,2,3")
+
+	err := Parse(&opts, r)
+	if err != nil {
+		failFatal(t, "Unexpected error", nil, err)
+	}
+
+	want := []int{1, 2, 3}
+	if !reflect.DeepEqual(opts.IDs, want) {
+		failFatal(t, "Test failed", want, opts.IDs)
+	}
+	pass(t, "Test passed", want, opts.IDs)
+}
+
+func TestParseIntMap(t *testing.T) {
+	type testStruct struct {
+		Limits map[string]int `qparams:"ops:>,<"`
+	}
+
+	opts := testStruct{}
+	r := newRequest("foobar.com?limits=age>7")
+
+	err := Parse(&opts, r)
+	if err != nil {
+		failFatal(t, "Unexpected error", nil, err)
+	}
+
+	want := map[string]int{"age >": 7}
+	if !reflect.DeepEqual(opts.Limits, want) {
+		failFatal(t, "Test failed", want, opts.Limits)
+	}
+	pass(t, "Test passed", want, opts.Limits)
+}
+
+func TestParseBoolField(t *testing.T) {
+	type testStruct struct {
+		Active bool
+	}
+
+	opts := testStruct{}
+	r := newRequest("foobar.com?active=true")
+
+	err := Parse(&opts, r)
+	if err != nil {
+		failFatal(t, "Unexpected error", nil, err)
+	}
+
+	if !opts.Active {
+		failFatal(t, "Test failed", true, opts.Active)
+	}
+	pass(t, "Test passed", true, opts.Active)
+}
+
+func TestRegisterConverter(t *testing.T) {
+	type celsius float64
+
+	type testStruct struct {
+		Temp celsius
+	}
+
+	RegisterConverter(reflect.TypeOf(celsius(0)), func(value string) (reflect.Value, error) {
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(celsius(f)), nil
+	})
+
+	opts := testStruct{}
+	r := newRequest("foobar.com?temp=36.6")
+
+	err := Parse(&opts, r)
+	if err != nil {
+		failFatal(t, "Unexpected error", nil, err)
+	}
+
+	want := celsius(36.6)
+	if opts.Temp != want {
+		failFatal(t, "Test failed", want, opts.Temp)
+	}
+	pass(t, "Test passed", want, opts.Temp)
+}
+
+// MARK - Nested struct / bracket key tests
+
+func TestParseNestedStruct(t *testing.T) {
+	type user struct {
+		Name string
+		Age  int
+	}
+
+	type testStruct struct {
+		User user
+	}
+
+	opts := testStruct{}
+	r := newRequest("foobar.com?user[name]=Bob&user[age]=30")
+
+	err := Parse(&opts, r)
+	if err != nil {
+		failFatal(t, "Unexpected error", nil, err)
+	}
+
+	want := user{Name: "Bob", Age: 30}
+	if opts.User != want {
+		failFatal(t, "Test failed", want, opts.User)
+	}
+	pass(t, "Test passed", want, opts.User)
+}
+
+func TestParseNestedPointerStruct(t *testing.T) {
+	type user struct {
+		Name string
+	}
+
+	type testStruct struct {
+		User *user
+	}
+
+	opts := testStruct{}
+	r := newRequest("foobar.com?user[name]=Bob")
+
+	err := Parse(&opts, r)
+	if err != nil {
+		failFatal(t, "Unexpected error", nil, err)
+	}
+
+	if opts.User == nil || opts.User.Name != "Bob" {
+		failFatal(t, "Test failed", "Bob", opts.User)
+	}
+	pass(t, "Test passed", "Bob", opts.User.Name)
+}
+
+func TestParseIndexedSlice(t *testing.T) {
+	type testStruct struct {
+		Filter []string
+	}
+
+	opts := testStruct{}
+	r := newRequest("foobar.com?filter[0]=a&filter[1]=b")
+
+	err := Parse(&opts, r)
+	if err != nil {
+		failFatal(t, "Unexpected error", nil, err)
+	}
+
+	want := []string{"a", "b"}
+	if !reflect.DeepEqual(opts.Filter, want) {
+		failFatal(t, "Test failed", want, opts.Filter)
+	}
+	pass(t, "Test passed", want, opts.Filter)
+}
+
+func TestParseIndexedMap(t *testing.T) {
+	type testStruct struct {
+		M Map
+	}
+
+	opts := testStruct{}
+	r := newRequest("foobar.com?m[a]=1&m[b]=2")
+
+	err := Parse(&opts, r)
+	if err != nil {
+		failFatal(t, "Unexpected error", nil, err)
+	}
+
+	want := Map{"a": "1", "b": "2"}
+	if !reflect.DeepEqual(opts.M, want) {
+		failFatal(t, "Test failed", want, opts.M)
+	}
+	pass(t, "Test passed", want, opts.M)
+}
+
+func TestParseEmbeddedStruct(t *testing.T) {
+	type Base struct {
+		ID int
+	}
+
+	type testStruct struct {
+		Base
+		Name string
+	}
+
+	opts := testStruct{}
+	r := newRequest("foobar.com?id=7&name=Bob")
+
+	err := Parse(&opts, r)
+	if err != nil {
+		failFatal(t, "Unexpected error", nil, err)
+	}
+
+	if opts.ID != 7 || opts.Name != "Bob" {
+		failFatal(t, "Test failed", "ID:7 Name:Bob", opts)
+	}
+	pass(t, "Test passed", "ID:7 Name:Bob", opts)
+}
+
+// MARK - Validation / default tests
+
+func TestParseDefault(t *testing.T) {
+	type testStruct struct {
+		Page int `qparams:"default:1"`
+	}
+
+	opts := testStruct{}
+	r := newRequest("foobar.com?")
+
+	err := Parse(&opts, r)
+	if err != nil {
+		failFatal(t, "Unexpected error", nil, err)
+	}
+
+	if opts.Page != 1 {
+		failFatal(t, "Test failed", 1, opts.Page)
+	}
+	pass(t, "Test passed", 1, opts.Page)
+}
+
+func TestParseRequired(t *testing.T) {
+	type testStruct struct {
+		Name string `qparams:"required"`
+	}
+
+	opts := testStruct{}
+	r := newRequest("foobar.com?")
+
+	err := Parse(&opts, r)
+	if err == nil {
+		failFatal(t, "Expected error", "Field Name is required", nil)
+	}
+	pass(t, "Test passed", "Field Name is required", err)
+}
+
+func TestParseMinMax(t *testing.T) {
+	type testStruct struct {
+		Age int `qparams:"min:18 max:65"`
+	}
+
+	opts := testStruct{}
+	r := newRequest("foobar.com?age=10")
+
+	err := Parse(&opts, r)
+	if err == nil {
+		failFatal(t, "Expected error", "Field Age is less than the minimum", nil)
+	}
+	pass(t, "Test passed", "Field Age is less than the minimum", err)
+}
+
+func TestParseEnum(t *testing.T) {
+	type testStruct struct {
+		Status string `qparams:"enum:active|inactive"`
+	}
+
+	opts := testStruct{}
+	r := newRequest("foobar.com?status=unknown")
+
+	err := Parse(&opts, r)
+	if err == nil {
+		failFatal(t, "Expected error", "Field Status is not one of active|inactive", nil)
+	}
+	pass(t, "Test passed", "Field Status is not one of active|inactive", err)
+
+	opts = testStruct{}
+	r = newRequest("foobar.com?status=active")
+
+	err = Parse(&opts, r)
+	if err != nil {
+		failFatal(t, "Unexpected error", nil, err)
+	}
+	pass(t, "Test passed", "active", opts.Status)
+}
+
+func TestPrecompile(t *testing.T) {
+	type testStruct struct {
+		Name string
+		Age  int `qparams:"min:18 max:65"`
+	}
+
+	Precompile(&testStruct{})
+
+	opts := testStruct{}
+	r := newRequest("foobar.com?name=Bob&age=30")
+
+	err := Parse(&opts, r)
+	if err != nil {
+		failFatal(t, "Unexpected error", nil, err)
+	}
+	pass(t, "Test passed", testStruct{Name: "Bob", Age: 30}, opts)
+}
+
+func TestPrecompileNonStruct(t *testing.T) {
+	Precompile(42)
+	Precompile(nil)
+}
+
+func TestParseReusesStructMeta(t *testing.T) {
+	type testStruct struct {
+		Status string `qparams:"enum:active|inactive"`
+	}
+
+	for i := 0; i < 3; i++ {
+		opts := testStruct{}
+		r := newRequest("foobar.com?status=active")
+
+		err := Parse(&opts, r)
+		if err != nil {
+			failFatal(t, "Unexpected error", nil, err)
+		}
+		pass(t, "Test passed", "active", opts.Status)
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	cases := map[string]string{
+		"UserID":   "user_id",
+		"Name":     "name",
+		"HTTPBody": "http_body",
+	}
+
+	for in, want := range cases {
+		got := SnakeCase(in)
+		if got != want {
+			failFatal(t, "SnakeCase mismatch", want, got)
+		}
+		pass(t, "Test passed", want, got)
+	}
+}
+
+func TestKebabCase(t *testing.T) {
+	got := KebabCase("UserID")
+	want := "user-id"
+	if got != want {
+		failFatal(t, "KebabCase mismatch", want, got)
+	}
+	pass(t, "Test passed", want, got)
+}
+
+func TestSetNameMapper(t *testing.T) {
+	type testStruct struct {
+		UserID string
+	}
+
+	SetNameMapper(SnakeCase)
+	defer SetNameMapper(LowerCase)
+
+	opts := testStruct{}
+	r := newRequest("foobar.com?user_id=42")
+
+	err := Parse(&opts, r)
+	if err != nil {
+		failFatal(t, "Unexpected error", nil, err)
+	}
+	pass(t, "Test passed", "42", opts.UserID)
+}
+
+func TestParseWithNameMapper(t *testing.T) {
+	type testStruct struct {
+		UserID string
+	}
+
+	opts := testStruct{}
+	r := newRequest("foobar.com?user_id=42")
+
+	err := ParseWith(&opts, r, WithNameMapper(SnakeCase))
+	if err != nil {
+		failFatal(t, "Unexpected error", nil, err)
+	}
+	pass(t, "Test passed", "42", opts.UserID)
+
+	// the package-wide default (LowerCase) is unaffected by the
+	// per-call override above
+	opts2 := testStruct{}
+	r2 := newRequest("foobar.com?userid=42")
+
+	err = Parse(&opts2, r2)
+	if err != nil {
+		failFatal(t, "Unexpected error", nil, err)
+	}
+	pass(t, "Test passed", "42", opts2.UserID)
+}
+
+func TestParseRepeatedSliceKeys(t *testing.T) {
+	type testStruct struct {
+		Tag Slice
+	}
+
+	opts := testStruct{}
+	r := newRequest("foobar.com?tag=a&tag=b&tag=c")
+
+	err := Parse(&opts, r)
+	if err != nil {
+		failFatal(t, "Unexpected error", nil, err)
+	}
+
+	want := Slice{"a", "b", "c"}
+	if !reflect.DeepEqual(opts.Tag, want) {
+		failFatal(t, "Test failed", want, opts.Tag)
+	}
+	pass(t, "Test passed", want, opts.Tag)
+}
+
+func TestParseRepeatedMapKeys(t *testing.T) {
+	type testStruct struct {
+		Filter Map `qparams:"ops:==,>,<"`
+	}
+
+	opts := testStruct{}
+	r := newRequest("foobar.com?filter=age>1&filter=name==bob")
+
+	err := Parse(&opts, r)
+	if err != nil {
+		failFatal(t, "Unexpected error", nil, err)
+	}
+
+	want := Map{"age >": "1", "name ==": "bob"}
+	if !reflect.DeepEqual(opts.Filter, want) {
+		failFatal(t, "Test failed", want, opts.Filter)
+	}
+	pass(t, "Test passed", want, opts.Filter)
+}
+
+func TestParseRepeatedMapKeysCustomSeparator(t *testing.T) {
+	type testStruct struct {
+		Filter Map `qparams:"ops:==,>,< sep:;"`
+	}
+
+	opts := testStruct{}
+	r := newRequest("foobar.com?filter=age>1&filter=name==bob")
+
+	err := Parse(&opts, r)
+	if err != nil {
+		failFatal(t, "Unexpected error", nil, err)
+	}
+
+	want := Map{"age >": "1", "name ==": "bob"}
+	if !reflect.DeepEqual(opts.Filter, want) {
+		failFatal(t, "Test failed", want, opts.Filter)
+	}
+	pass(t, "Test passed", want, opts.Filter)
+}